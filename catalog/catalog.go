@@ -0,0 +1,121 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog records which episodes have already been turned into
+// YouTube videos, so that repeat runs against the same feed can skip
+// everything that was already published.
+package catalog
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Entry is what the catalog knows about a single published episode.
+type Entry struct {
+	GUID         string
+	VideoID      string
+	UploadedAt   time.Time
+	MP3Checksum  string
+	EncodeParams string
+}
+
+// Catalog is a SQLite-backed, GUID-keyed record of published episodes. It
+// requires no cgo, via modernc.org/sqlite.
+type Catalog struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the catalog database at path.
+func Open(path string) (*Catalog, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open catalog %s: %v", path, err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS episodes (
+	guid          TEXT PRIMARY KEY,
+	video_id      TEXT NOT NULL,
+	uploaded_at   TIMESTAMP NOT NULL,
+	mp3_checksum  TEXT NOT NULL,
+	encode_params TEXT NOT NULL
+)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create schema in %s: %v", path, err)
+	}
+	return &Catalog{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (c *Catalog) Close() error {
+	return c.db.Close()
+}
+
+// Get returns the entry recorded for guid, if any.
+func (c *Catalog) Get(guid string) (Entry, bool, error) {
+	row := c.db.QueryRow(`SELECT guid, video_id, uploaded_at, mp3_checksum, encode_params FROM episodes WHERE guid = ?`, guid)
+
+	var e Entry
+	if err := row.Scan(&e.GUID, &e.VideoID, &e.UploadedAt, &e.MP3Checksum, &e.EncodeParams); err == sql.ErrNoRows {
+		return Entry{}, false, nil
+	} else if err != nil {
+		return Entry{}, false, fmt.Errorf("could not look up %s: %v", guid, err)
+	}
+	return e, true, nil
+}
+
+// Put records or replaces the entry for e.GUID.
+func (c *Catalog) Put(e Entry) error {
+	_, err := c.db.Exec(
+		`INSERT INTO episodes (guid, video_id, uploaded_at, mp3_checksum, encode_params) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(guid) DO UPDATE SET video_id = excluded.video_id, uploaded_at = excluded.uploaded_at,
+			mp3_checksum = excluded.mp3_checksum, encode_params = excluded.encode_params`,
+		e.GUID, e.VideoID, e.UploadedAt, e.MP3Checksum, e.EncodeParams)
+	if err != nil {
+		return fmt.Errorf("could not record %s: %v", e.GUID, err)
+	}
+	return nil
+}
+
+// Forget removes the entry for guid, if any, so a later run will
+// reprocess and re-upload that episode.
+func (c *Catalog) Forget(guid string) error {
+	if _, err := c.db.Exec(`DELETE FROM episodes WHERE guid = ?`, guid); err != nil {
+		return fmt.Errorf("could not forget %s: %v", guid, err)
+	}
+	return nil
+}
+
+// List returns every recorded entry, ordered by upload time.
+func (c *Catalog) List() ([]Entry, error) {
+	rows, err := c.db.Query(`SELECT guid, video_id, uploaded_at, mp3_checksum, encode_params FROM episodes ORDER BY uploaded_at`)
+	if err != nil {
+		return nil, fmt.Errorf("could not list catalog: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.GUID, &e.VideoID, &e.UploadedAt, &e.MP3Checksum, &e.EncodeParams); err != nil {
+			return nil, fmt.Errorf("could not scan catalog row: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
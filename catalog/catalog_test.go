@@ -0,0 +1,111 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCatalogRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.db")
+
+	cat, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer cat.Close()
+
+	if _, ok, err := cat.Get("guid-1"); err != nil {
+		t.Fatalf("Get on empty catalog: %v", err)
+	} else if ok {
+		t.Fatal("Get on empty catalog returned ok = true")
+	}
+
+	entry := Entry{
+		GUID:         "guid-1",
+		VideoID:      "vid-1",
+		UploadedAt:   time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+		MP3Checksum:  "abc123",
+		EncodeParams: "128kbps",
+	}
+	if err := cat.Put(entry); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, ok, err := cat.Get("guid-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !ok {
+		t.Fatal("Get after Put returned ok = false")
+	}
+	if !got.UploadedAt.Equal(entry.UploadedAt) {
+		t.Errorf("Get().UploadedAt = %v, want %v", got.UploadedAt, entry.UploadedAt)
+	}
+	got.UploadedAt = entry.UploadedAt // time.Time doesn't compare equal with ==
+	if got != entry {
+		t.Errorf("Get() = %+v, want %+v", got, entry)
+	}
+
+	// Put again with the same GUID replaces the entry rather than erroring.
+	entry.VideoID = "vid-1-reuploaded"
+	if err := cat.Put(entry); err != nil {
+		t.Fatalf("Put (update): %v", err)
+	}
+	got, _, err = cat.Get("guid-1")
+	if err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	if got.VideoID != "vid-1-reuploaded" {
+		t.Errorf("Get().VideoID = %q, want %q", got.VideoID, "vid-1-reuploaded")
+	}
+
+	other := Entry{
+		GUID:         "guid-2",
+		VideoID:      "vid-2",
+		UploadedAt:   entry.UploadedAt.Add(time.Hour),
+		MP3Checksum:  "def456",
+		EncodeParams: "128kbps",
+	}
+	if err := cat.Put(other); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entries, err := cat.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 || entries[0].GUID != "guid-1" || entries[1].GUID != "guid-2" {
+		t.Errorf("List() = %+v, want guid-1 then guid-2", entries)
+	}
+
+	if err := cat.Forget("guid-1"); err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if _, ok, err := cat.Get("guid-1"); err != nil {
+		t.Fatalf("Get after Forget: %v", err)
+	} else if ok {
+		t.Fatal("Get after Forget returned ok = true")
+	}
+
+	entries, err = cat.List()
+	if err != nil {
+		t.Fatalf("List after Forget: %v", err)
+	}
+	if len(entries) != 1 || entries[0].GUID != "guid-2" {
+		t.Errorf("List() after Forget = %+v, want just guid-2", entries)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/campoy/podcast-to-youtube/catalog"
+	"github.com/campoy/podcast-to-youtube/source"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List episodes already published to YouTube",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cat, err := catalog.Open(*catalogPath)
+		if err != nil {
+			return err
+		}
+		defer cat.Close()
+
+		entries, err := cat.List()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("%s\t%s\t%s\n", e.UploadedAt.Format("2006-01-02 15:04"), e.VideoID, e.GUID)
+		}
+		return nil
+	},
+}
+
+var forgetCmd = &cobra.Command{
+	Use:   "forget <guid>",
+	Short: "Remove an episode from the catalog, so the next run re-uploads it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guid := args[0]
+
+		cat, err := catalog.Open(*catalogPath)
+		if err != nil {
+			return err
+		}
+		defer cat.Close()
+		if err := cat.Forget(guid); err != nil {
+			return err
+		}
+
+		// Forgetting only touches local cache files, so a plain,
+		// unauthenticated client is enough; no YouTube call is made.
+		up, err := uploaderFor(http.DefaultClient)
+		if err != nil {
+			return err
+		}
+		return up.Forget(guid)
+	},
+}
+
+var reuploadCmd = &cobra.Command{
+	Use:   "reupload <guid>",
+	Short: "Forget and immediately re-encode and re-upload a single episode",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guid := args[0]
+		ctx := context.Background()
+
+		cat, err := catalog.Open(*catalogPath)
+		if err != nil {
+			return err
+		}
+		defer cat.Close()
+
+		client, err := authedClient()
+		if err != nil {
+			return fmt.Errorf("could not authenticate with YouTube: %v", err)
+		}
+
+		src, err := source.New(*sourceKind, source.Config{URL: *rssFeed, ChannelID: *channelID, Client: client})
+		if err != nil {
+			return err
+		}
+		eps, err := src.FetchEpisodes(ctx)
+		if err != nil {
+			return err
+		}
+		var ep *source.Episode
+		for i := range eps {
+			if eps[i].Link == guid {
+				ep = &eps[i]
+				break
+			}
+		}
+		if ep == nil {
+			return fmt.Errorf("no episode with GUID %s in the feed", guid)
+		}
+
+		up, err := uploaderFor(client)
+		if err != nil {
+			return err
+		}
+		defer up.Wait()
+
+		if err := cat.Forget(guid); err != nil {
+			return err
+		}
+		if err := up.Forget(guid); err != nil {
+			return err
+		}
+
+		return process(ctx, up, cat, *ep)
+	},
+}
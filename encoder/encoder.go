@@ -0,0 +1,102 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encoder probes a podcast's MP3 and turns it into an adaptive
+// ffmpeg encoding plan: the AAC bitrate, loudness normalization, faststart,
+// and chapter markers to use when rendering the episode's video.
+package encoder
+
+import (
+	"image/color"
+	"time"
+)
+
+// Chapter is a single chapter marker to embed in the rendered video.
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+// Visual selects how the video's background is rendered.
+type Visual string
+
+const (
+	// VisualStatic loops a single still image for the whole video. This
+	// is the default and matches the original, image-only behavior.
+	VisualStatic Visual = "static"
+	// VisualWaveform overlays an audio-reactive waveform on the still
+	// image, using ffmpeg's showwaves filter.
+	VisualWaveform Visual = "waveform"
+	// VisualSpectrum overlays an audio-reactive spectrum on the still
+	// image, using ffmpeg's showspectrum filter.
+	VisualSpectrum Visual = "spectrum"
+)
+
+// VisualSpec configures an audio-reactive visual mode.
+type VisualSpec struct {
+	Mode       Visual
+	Width      int
+	Height     int
+	Foreground color.Color
+}
+
+// EncodePlan describes how ffmpeg should encode a given episode, chosen
+// from the result of probing its MP3.
+type EncodePlan struct {
+	// MP3Path is the source audio probed to produce this plan.
+	MP3Path string
+	// AudioBitrateKbps is the target AAC bitrate, chosen from the
+	// source MP3's bitrate.
+	AudioBitrateKbps int
+	// SampleRate and Channels carry over the source MP3's layout so we
+	// don't up- or down-sample unnecessarily.
+	SampleRate int
+	Channels   int
+	// Faststart moves the MP4 moov atom to the front of the file so
+	// players can start streaming before the full download completes.
+	Faststart bool
+	// Normalize enables a two-pass loudnorm filter that measures the
+	// source audio's loudness in a first pass and normalizes it in the
+	// second. It defaults to on; Run measures the actual parameters.
+	Normalize bool
+	// Chapters are embedded as MP4 chapter metadata.
+	Chapters []Chapter
+	// Visual selects the video's background rendering; the zero value
+	// is VisualStatic.
+	Visual VisualSpec
+}
+
+// loudnormMeasurement is what ffmpeg's loudnorm filter reports in its
+// first, analysis-only pass, fed back into the second pass.
+type loudnormMeasurement struct {
+	InputI, InputTP, InputLRA, InputThresh string
+	TargetOffset                           string
+}
+
+// bitrateFor picks an AAC encoding bitrate for a source MP3 of the given
+// bitrate. It never exceeds the source bitrate, except that sources below
+// 96 kbps still get 96 kbps; an unknown (zero) source bitrate gets 160.
+func bitrateFor(sourceKbps int) int {
+	switch {
+	case sourceKbps <= 0:
+		return 160
+	case sourceKbps < 96:
+		return 96
+	case sourceKbps < 160:
+		return 128
+	case sourceKbps < 256:
+		return 160
+	default:
+		return 192
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import "context"
+
+// Plan probes the MP3 at mp3Path and chooses encoding parameters for it:
+// an AAC bitrate appropriate for the source bitrate, the source's sample
+// rate and channel layout, and faststart plus loudness normalization
+// enabled by default. visual selects the background rendering; its zero
+// value is VisualSpec{Mode: VisualStatic}. Callers that have chapter
+// markers for the episode should set the returned plan's Chapters field
+// before calling Run.
+func Plan(mp3Path string, visual VisualSpec) (EncodePlan, error) {
+	p, err := probe(context.Background(), mp3Path)
+	if err != nil {
+		return EncodePlan{}, err
+	}
+	if visual.Mode == "" {
+		visual.Mode = VisualStatic
+	}
+
+	return EncodePlan{
+		MP3Path:          mp3Path,
+		AudioBitrateKbps: bitrateFor(p.BitrateKbs),
+		SampleRate:       p.SampleRate,
+		Channels:         p.Channels,
+		Faststart:        true,
+		Normalize:        true,
+		Visual:           visual,
+	}, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// withFakeFfprobe puts a fake ffprobe binary that prints fixture ahead of
+// the real one on PATH, restoring it afterwards.
+func withFakeFfprobe(t *testing.T, fixture string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffprobe script is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "fake-ffprobe")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	script := "#!/bin/sh\ncat <<'EOF'\n" + fixture + "\nEOF\n"
+	path := filepath.Join(dir, "ffprobe")
+	if err := ioutil.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+	t.Cleanup(func() { os.Setenv("PATH", oldPath) })
+}
+
+const fakeProbeOutput = `{
+  "streams": [
+    {"codec_type": "audio", "sample_rate": "44100", "channels": 2}
+  ],
+  "format": {"duration": "1800.0", "bit_rate": "128000"}
+}`
+
+func TestPlan(t *testing.T) {
+	withFakeFfprobe(t, fakeProbeOutput)
+
+	plan, err := Plan("episode.mp3", VisualSpec{})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+
+	if plan.AudioBitrateKbps != 128 {
+		t.Errorf("AudioBitrateKbps = %d, want 128", plan.AudioBitrateKbps)
+	}
+	if plan.SampleRate != 44100 {
+		t.Errorf("SampleRate = %d, want 44100", plan.SampleRate)
+	}
+	if plan.Channels != 2 {
+		t.Errorf("Channels = %d, want 2", plan.Channels)
+	}
+	if !plan.Faststart {
+		t.Error("Faststart = false, want true")
+	}
+	if !plan.Normalize {
+		t.Error("Normalize = false, want true")
+	}
+	if plan.Visual.Mode != VisualStatic {
+		t.Errorf("Visual.Mode = %q, want %q", plan.Visual.Mode, VisualStatic)
+	}
+}
+
+func TestBitrateFor(t *testing.T) {
+	tests := []struct {
+		source int
+		want   int
+	}{
+		{0, 160},
+		{64, 96},
+		{128, 128},
+		{192, 160},
+		{320, 192},
+	}
+	for _, tt := range tests {
+		if got := bitrateFor(tt.source); got != tt.want {
+			t.Errorf("bitrateFor(%d) = %d, want %d", tt.source, got, tt.want)
+		}
+	}
+}
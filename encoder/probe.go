@@ -0,0 +1,63 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vansante/go-ffprobe"
+)
+
+// probeResult is the subset of ffprobe's output that Plan cares about.
+type probeResult struct {
+	Duration   time.Duration
+	BitrateKbs int
+	SampleRate int
+	Channels   int
+}
+
+// probe runs ffprobe on the given MP3 and extracts duration, bitrate,
+// sample rate, and channel layout. It requires ffprobe to be installed.
+// See https://ffmpeg.org for installation instructions.
+func probe(ctx context.Context, mp3Path string) (probeResult, error) {
+	data, err := ffprobe.GetProbeData(mp3Path, 5*time.Second)
+	if err != nil {
+		return probeResult{}, fmt.Errorf("could not probe %s: %v", mp3Path, err)
+	}
+
+	stream := data.GetFirstAudioStream()
+	if stream == nil {
+		return probeResult{}, fmt.Errorf("%s has no audio stream", mp3Path)
+	}
+
+	bitrate, err := strconv.Atoi(data.Format.BitRate)
+	if err != nil {
+		bitrate = 0
+	}
+
+	sampleRate, err := strconv.Atoi(stream.SampleRate)
+	if err != nil {
+		sampleRate = 0
+	}
+
+	return probeResult{
+		Duration:   data.Format.Duration(),
+		BitrateKbs: bitrate / 1000,
+		SampleRate: sampleRate,
+		Channels:   stream.Channels,
+	}, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+)
+
+const loudnormFilter = "loudnorm=I=-16:TP=-1.5:LRA=11"
+
+// Run renders plan into the video at out, with img as the background for
+// the audio at plan.MP3Path. When plan.Visual.Mode is VisualStatic (the
+// default), img is simply looped; otherwise a showwaves or showspectrum
+// filtergraph is composited over it for an audio-reactive background.
+// When plan.Normalize is set, a first, analysis-only ffmpeg pass measures
+// the source loudness and a second pass applies it; otherwise a single
+// pass is run. This function requires ffmpeg to be installed. See
+// https://ffmpeg.org for installation instructions.
+func Run(plan EncodePlan, img, out string) error {
+	filter := ""
+	if plan.Normalize {
+		m, err := measureLoudness(plan.MP3Path)
+		if err != nil {
+			return fmt.Errorf("could not measure loudness: %v", err)
+		}
+		filter = fmt.Sprintf("%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true",
+			loudnormFilter, m.InputI, m.InputTP, m.InputLRA, m.InputThresh, m.TargetOffset)
+	}
+
+	args := []string{"-y", "-loop", "1", "-i", img, "-i", plan.MP3Path}
+
+	var chapterFile string
+	if len(plan.Chapters) > 0 {
+		f, err := writeChaptersFile(plan.Chapters)
+		if err != nil {
+			return fmt.Errorf("could not write chapter metadata: %v", err)
+		}
+		chapterFile = f
+		defer os.Remove(chapterFile)
+		args = append(args, "-i", chapterFile, "-map_metadata", "2")
+	}
+
+	if plan.Visual.Mode != "" && plan.Visual.Mode != VisualStatic {
+		args = append(args, "-filter_complex", visualFilterGraph(plan.Visual), "-map", "[v]", "-map", "1:a")
+	}
+
+	args = append(args, "-shortest", "-c:v", "libx264", "-pix_fmt", "yuv420p")
+	if filter != "" {
+		args = append(args, "-af", filter)
+	}
+	args = append(args,
+		"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", plan.AudioBitrateKbps),
+		"-ar", fmt.Sprintf("%d", plan.SampleRate),
+		"-ac", fmt.Sprintf("%d", plan.Channels),
+		"-crf", "18",
+	)
+	if plan.Faststart {
+		args = append(args, "-movflags", "+faststart")
+	}
+	args = append(args, out)
+
+	cmd := exec.Command("ffmpeg", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// measureLoudness runs ffmpeg's loudnorm filter in its analysis-only mode
+// against mp3Path and parses the JSON block it prints to stderr.
+func measureLoudness(mp3Path string) (loudnormMeasurement, error) {
+	cmd := exec.Command("ffmpeg", "-i", mp3Path, "-af", loudnormFilter+":print_format=json", "-f", "null", "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return loudnormMeasurement{}, fmt.Errorf("%v: %s", err, stderr.String())
+	}
+
+	match := regexp.MustCompile(`(?s)\{.*\}`).FindString(stderr.String())
+	if match == "" {
+		return loudnormMeasurement{}, fmt.Errorf("no loudnorm measurement found in ffmpeg output")
+	}
+
+	var m struct {
+		InputI       string `json:"input_i"`
+		InputTP      string `json:"input_tp"`
+		InputLRA     string `json:"input_lra"`
+		InputThresh  string `json:"input_thresh"`
+		TargetOffset string `json:"target_offset"`
+	}
+	if err := json.Unmarshal([]byte(match), &m); err != nil {
+		return loudnormMeasurement{}, fmt.Errorf("could not parse loudnorm measurement: %v", err)
+	}
+	return loudnormMeasurement{
+		InputI:       m.InputI,
+		InputTP:      m.InputTP,
+		InputLRA:     m.InputLRA,
+		InputThresh:  m.InputThresh,
+		TargetOffset: m.TargetOffset,
+	}, nil
+}
+
+// writeChaptersFile renders chapters as an ffmetadata file ffmpeg can read
+// with -map_metadata, and returns its path in a temp directory.
+func writeChaptersFile(chapters []Chapter) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(";FFMETADATA1\n")
+	for i, c := range chapters {
+		start := c.Start.Milliseconds()
+		end := int64(1<<63 - 1)
+		if i+1 < len(chapters) {
+			end = chapters[i+1].Start.Milliseconds()
+		}
+		fmt.Fprintf(&buf, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n", start, end, c.Title)
+	}
+
+	f, err := ioutil.TempFile("", "chapters-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
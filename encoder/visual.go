@@ -0,0 +1,42 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// visualFilterGraph builds the -filter_complex graph for an audio-reactive
+// background: it draws the waveform or spectrum from input 1 (the audio)
+// and overlays it on input 0 (the still image), producing output pad [v].
+func visualFilterGraph(spec VisualSpec) string {
+	size := fmt.Sprintf("%dx%d", spec.Width, spec.Height)
+	switch spec.Mode {
+	case VisualSpectrum:
+		return fmt.Sprintf("[1:a]showspectrum=s=%s[vis];[0:v][vis]overlay=format=auto[v]", size)
+	default: // VisualWaveform
+		return fmt.Sprintf("[1:a]showwaves=s=%s:mode=cline:colors=%s[vis];[0:v][vis]overlay=format=auto[v]",
+			size, hexColor(spec.Foreground))
+	}
+}
+
+// hexColor formats c as the 0xRRGGBB ffmpeg expects for its colors option.
+func hexColor(c color.Color) string {
+	if c == nil {
+		return "0xFFFFFF"
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("0x%02X%02X%02X", r>>8, g>>8, b>>8)
+}
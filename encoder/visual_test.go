@@ -0,0 +1,49 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoder
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestVisualFilterGraph(t *testing.T) {
+	tests := []struct {
+		spec VisualSpec
+		want string
+	}{
+		{
+			spec: VisualSpec{Mode: VisualWaveform, Width: 1280, Height: 720, Foreground: color.White},
+			want: "[1:a]showwaves=s=1280x720:mode=cline:colors=0xFFFFFF[vis];[0:v][vis]overlay=format=auto[v]",
+		},
+		{
+			spec: VisualSpec{Mode: VisualSpectrum, Width: 640, Height: 480},
+			want: "[1:a]showspectrum=s=640x480[vis];[0:v][vis]overlay=format=auto[v]",
+		},
+	}
+	for _, tt := range tests {
+		if got := visualFilterGraph(tt.spec); got != tt.want {
+			t.Errorf("visualFilterGraph(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	if got, want := hexColor(color.RGBA{0, 150, 136, 255}), "0x009688"; got != want {
+		t.Errorf("hexColor(...) = %q, want %q", got, want)
+	}
+	if got, want := hexColor(nil), "0xFFFFFF"; got != want {
+		t.Errorf("hexColor(nil) = %q, want %q", got, want)
+	}
+}
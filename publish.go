@@ -0,0 +1,342 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	stdimage "image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"google.golang.org/api/youtube/v3"
+
+	"github.com/campoy/podcast-to-youtube/catalog"
+	"github.com/campoy/podcast-to-youtube/encoder"
+	"github.com/campoy/podcast-to-youtube/image"
+	"github.com/campoy/podcast-to-youtube/source"
+	"github.com/campoy/podcast-to-youtube/transcript"
+	"github.com/campoy/podcast-to-youtube/uploader"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// runPublish fetches the feed and, unless --sync was given, interactively
+// asks which episodes to publish; it then encodes and uploads whichever
+// episodes are selected and not already in the catalog.
+func runPublish(ctx context.Context) error {
+	// The youtube source needs an authenticated client to call the YouTube
+	// Data API; the rss, atom, and jsonfeed sources ignore it, so we only
+	// pay for the interactive OAuth flow up front when it's actually needed.
+	var client *http.Client
+	if *sourceKind == "youtube" {
+		var err error
+		client, err = authedClient()
+		if err != nil {
+			return fmt.Errorf("could not authenticate with YouTube: %v", err)
+		}
+	}
+
+	src, err := source.New(*sourceKind, source.Config{URL: *rssFeed, ChannelID: *channelID, Client: client})
+	if err != nil {
+		return err
+	}
+	eps, err := src.FetchEpisodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	cat, err := catalog.Open(*catalogPath)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	var selected []source.Episode
+	if *syncMode {
+		selected = eps
+	} else {
+		fmt.Print("episode number to publish (try 1, or 2-10): ")
+		var answer string
+		fmt.Scanln(&answer)
+		from, to, err := parseRange(answer)
+		if err != nil {
+			return fmt.Errorf("%s is an invalid range", answer)
+		}
+		for _, e := range eps {
+			if from <= e.Number && e.Number <= to {
+				selected = append(selected, e)
+				fmt.Printf("episode %d: %s\n", e.Number, e.Title)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no episodes selected")
+	}
+
+	if !*syncMode {
+		fmt.Print("publish? (Y/n): ")
+		var answer string
+		fmt.Scanln(&answer)
+		if !(answer == "Y" || answer == "y" || answer == "") {
+			return nil
+		}
+	}
+
+	if client == nil {
+		client, err = authedClient()
+		if err != nil {
+			return fmt.Errorf("could not authenticate with YouTube: %v", err)
+		}
+	}
+
+	up, err := uploaderFor(client)
+	if err != nil {
+		return err
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(*concurrency)
+	for _, ep := range selected {
+		ep := ep
+		if _, ok, err := cat.Get(ep.Link); err != nil {
+			return err
+		} else if ok {
+			if !*syncMode {
+				fmt.Printf("episode %d already published, skipping\n", ep.Number)
+			}
+			continue
+		}
+		g.Go(func() error {
+			if err := process(ctx, up, cat, ep); err != nil {
+				return fmt.Errorf("episode %d: %v", ep.Number, err)
+			}
+			return nil
+		})
+	}
+	err = g.Wait()
+	up.Wait()
+	return err
+}
+
+// uploaderFor creates an Uploader using the standard -upload-cache and
+// -upload-qps flags.
+func uploaderFor(client *http.Client) (*uploader.Uploader, error) {
+	up, err := uploader.New(client, *uploadCache, *uploadQPS)
+	if err != nil {
+		return nil, fmt.Errorf("could not create uploader: %v", err)
+	}
+	return up, nil
+}
+
+// process creates the video for the given episode, uploads it to YouTube
+// using up, and records the result in cat.
+func process(ctx context.Context, up *uploader.Uploader, cat *catalog.Catalog, ep source.Episode) error {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		return fmt.Errorf("could not create temp directory: %v", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Printf("could not remove %s: %v", tmpDir, err)
+		}
+	}()
+
+	img, err := image.Generate(image.Params{
+		Logo:       *logo,
+		Text:       fmt.Sprintf("%d: %s", ep.Number, ep.Title),
+		Font:       *font,
+		Foreground: foreground,
+		Background: background,
+		Width:      *width,
+		Height:     *height,
+	})
+	if err != nil {
+		return fmt.Errorf("could not generate image: %v", err)
+	}
+
+	// We create the image and store it in the temp directory.
+	slide := filepath.Join(tmpDir, "slide.png")
+	if err := writePNG(slide, img); err != nil {
+		return fmt.Errorf("could not create image: %v", err)
+	}
+
+	// We download the MP3 so ffprobe and ffmpeg can work on a local file.
+	mp3 := filepath.Join(tmpDir, "audio.mp3")
+	if err := download(mp3, ep.MP3); err != nil {
+		return fmt.Errorf("could not download %s: %v", ep.MP3, err)
+	}
+	checksum, err := sha256File(mp3)
+	if err != nil {
+		return fmt.Errorf("could not checksum %s: %v", mp3, err)
+	}
+
+	// If --transcribe is set, whisper.cpp's transcript takes over chapter
+	// detection and supplies extra description lines, superseding any
+	// chapters the feed itself provided.
+	var srtPath string
+	var transcriptChapters []transcript.Chapter
+	chapters := ep.Chapters
+	if *transcribe {
+		var err error
+		srtPath, err = transcript.Transcribe(*whisperBin, mp3, *whisperModel, tmpDir)
+		if err != nil {
+			return fmt.Errorf("could not transcribe %s: %v", mp3, err)
+		}
+		srt, err := ioutil.ReadFile(srtPath)
+		if err != nil {
+			return fmt.Errorf("could not read transcript %s: %v", srtPath, err)
+		}
+		transcriptChapters = transcript.Segment(string(srt), *chapterThreshold)
+		chapters = nil
+		for _, c := range transcriptChapters {
+			chapters = append(chapters, source.Chapter{Start: c.Start, Title: c.Title})
+		}
+	}
+
+	// We probe the audio to choose encoding parameters, then render the video.
+	plan, err := encoder.Plan(mp3, encoder.VisualSpec{
+		Mode:       encoder.Visual(*visual),
+		Width:      *width,
+		Height:     *height,
+		Foreground: foreground,
+	})
+	if err != nil {
+		return fmt.Errorf("could not plan encoding: %v", err)
+	}
+	plan.Chapters = toEncoderChapters(chapters)
+
+	vid := filepath.Join(tmpDir, "vid.mp4")
+	if err := encoder.Run(plan, slide, vid); err != nil {
+		return fmt.Errorf("could not create video: %v\n", err)
+	}
+
+	// We generate the metadata for the YouTube upload.
+	var buf bytes.Buffer
+	if err := titleTmpl.Execute(&buf, ep); err != nil {
+		return fmt.Errorf("could not create video title from template: %v", err)
+	}
+
+	// We drop all the HTML tags and line breaks from the description.
+	desc := bluemonday.StrictPolicy().Sanitize(ep.Desc)
+	desc = strings.Replace(desc, "\n", " ", -1)
+	description := fmt.Sprintf("Original post: %s\n\n", ep.Link) + desc
+	if len(transcriptChapters) > 0 {
+		description += "\n\n" + transcript.DescriptionChapters(transcriptChapters)
+	}
+	data := &youtube.Video{
+		Snippet: &youtube.VideoSnippet{
+			Title:       buf.String(),
+			Description: description,
+			Tags:        append(ep.Tags, strings.Split(*tags, ",")...),
+		},
+		Status: &youtube.VideoStatus{PrivacyStatus: "unlisted"},
+	}
+
+	// And finally we upload the video to YouTube.
+	videoID, err := up.Upload(ctx, ep.Link, data, vid)
+	if err != nil {
+		return fmt.Errorf("could not upload to YouTube: %v", err)
+	}
+
+	if srtPath != "" {
+		if err := transcript.UploadCaptions(up.Service(), videoID, srtPath); err != nil {
+			return fmt.Errorf("could not upload captions: %v", err)
+		}
+	}
+
+	return cat.Put(catalog.Entry{
+		GUID:         ep.Link,
+		VideoID:      videoID,
+		UploadedAt:   time.Now().UTC(),
+		MP3Checksum:  checksum,
+		EncodeParams: encodeParamsString(plan),
+	})
+}
+
+// writePNG encodes the given image as a PNG file at the given path.
+func writePNG(path string, img stdimage.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %v", path, err)
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("could not encode to %s: %v", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not close %s: %v", path, err)
+	}
+	return nil
+}
+
+// download saves the content at url to the given filepath.
+func download(path, url string) error {
+	res, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, res.Body)
+	return err
+}
+
+// sha256File returns the hex-encoded SHA-256 checksum of the file at path.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// encodeParamsString summarizes the encoding parameters used for an
+// episode, for storage in the catalog.
+func encodeParamsString(plan encoder.EncodePlan) string {
+	return fmt.Sprintf("aac=%dkbps rate=%d channels=%d visual=%s", plan.AudioBitrateKbps, plan.SampleRate, plan.Channels, plan.Visual.Mode)
+}
+
+// toEncoderChapters converts the chapters parsed from a feed into the
+// shape the encoder package works with.
+func toEncoderChapters(chapters []source.Chapter) []encoder.Chapter {
+	var out []encoder.Chapter
+	for _, c := range chapters {
+		out = append(out, encoder.Chapter{Start: c.Start, Title: c.Title})
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// atomSource reads an Atom 1.0 feed. Episode numbers are assigned by
+// position, counting down from the most recent entry, since Atom has no
+// equivalent of <itunes:episode>.
+type atomSource struct {
+	url string
+}
+
+func (s *atomSource) FetchEpisodes(ctx context.Context) ([]Episode, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s: %v", s.url, err)
+	}
+	defer res.Body.Close()
+
+	var data struct {
+		XMLName xml.Name `xml:"feed"`
+		Entry   []struct {
+			Title   string `xml:"title"`
+			Summary string `xml:"summary"`
+			Content string `xml:"content"`
+			Link    []struct {
+				Rel  string `xml:"rel,attr"`
+				Type string `xml:"type,attr"`
+				HREF string `xml:"href,attr"`
+			} `xml:"link"`
+			Category []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+
+	if err := xml.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("could not decode feed: %v", err)
+	}
+
+	n := len(data.Entry)
+	var eps []Episode
+	for _, e := range data.Entry {
+		var link, mp3 string
+		for _, l := range e.Link {
+			switch {
+			case strings.HasPrefix(l.Type, "audio/"):
+				mp3 = l.HREF
+			case l.Rel == "" || l.Rel == "alternate":
+				link = l.HREF
+			}
+		}
+		var tags []string
+		for _, c := range e.Category {
+			tags = append(tags, c.Term)
+		}
+		desc := e.Summary
+		if desc == "" {
+			desc = e.Content
+		}
+		eps = append(eps, Episode{
+			Title:  e.Title,
+			Number: n,
+			Link:   link,
+			Desc:   desc,
+			MP3:    mp3,
+			Tags:   tags,
+		})
+		n--
+	}
+	return eps, nil
+}
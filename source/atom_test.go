@@ -0,0 +1,53 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAtomSource(t *testing.T) {
+	srv := serveFile(t, "testdata/feed.atom")
+	defer srv.Close()
+
+	src := &atomSource{url: srv.URL}
+	eps, err := src.FetchEpisodes(context.Background())
+	if err != nil {
+		t.Fatalf("FetchEpisodes: %v", err)
+	}
+
+	want := []Episode{
+		{
+			Title:  "Episode two",
+			Number: 2,
+			Link:   "https://example.com/episodes/2",
+			Desc:   "The second episode.",
+			MP3:    "https://example.com/episodes/2.mp3",
+			Tags:   []string{"podcast"},
+		},
+		{
+			Title:  "Episode one",
+			Number: 1,
+			Link:   "https://example.com/episodes/1",
+			Desc:   "The first episode.",
+			MP3:    "https://example.com/episodes/1.mp3",
+			Tags:   []string{"podcast"},
+		},
+	}
+	if !reflect.DeepEqual(eps, want) {
+		t.Errorf("FetchEpisodes() = %+v, want %+v", eps, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jsonFeedSource reads a JSON Feed 1.1 document
+// (https://www.jsonfeed.org/version/1.1/). Like Atom, JSON Feed has no
+// episode number, so items are numbered by position, counting down from
+// the most recent.
+type jsonFeedSource struct {
+	url string
+}
+
+func (s *jsonFeedSource) FetchEpisodes(ctx context.Context) ([]Episode, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s: %v", s.url, err)
+	}
+	defer res.Body.Close()
+
+	var data struct {
+		Version string `json:"version"`
+		Items   []struct {
+			Title       string   `json:"title"`
+			URL         string   `json:"url"`
+			Summary     string   `json:"summary"`
+			ContentText string   `json:"content_text"`
+			Tags        []string `json:"tags"`
+			Attachments []struct {
+				URL      string `json:"url"`
+				MIMEType string `json:"mime_type"`
+			} `json:"attachments"`
+		} `json:"items"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("could not decode feed: %v", err)
+	}
+
+	n := len(data.Items)
+	var eps []Episode
+	for _, i := range data.Items {
+		var mp3 string
+		for _, a := range i.Attachments {
+			if strings.HasPrefix(a.MIMEType, "audio/") {
+				mp3 = a.URL
+				break
+			}
+		}
+		desc := i.Summary
+		if desc == "" {
+			desc = i.ContentText
+		}
+		eps = append(eps, Episode{
+			Title:  i.Title,
+			Number: n,
+			Link:   i.URL,
+			Desc:   desc,
+			MP3:    mp3,
+			Tags:   i.Tags,
+		})
+		n--
+	}
+	return eps, nil
+}
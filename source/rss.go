@@ -0,0 +1,119 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rssSource reads a standard RSS 2.0 feed using the iTunes podcast
+// namespace (<itunes:episode>, <itunes:summary>, <itunes:image>) for the
+// fields that plain RSS doesn't provide.
+type rssSource struct {
+	url string
+}
+
+func (s *rssSource) FetchEpisodes(ctx context.Context) ([]Episode, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("could not get %s: %v", s.url, err)
+	}
+	defer res.Body.Close()
+
+	var data struct {
+		XMLName xml.Name `xml:"rss"`
+		Channel []struct {
+			Item []struct {
+				Title   string `xml:"title"`
+				Episode int    `xml:"episode"`
+				Link    string `xml:"guid"`
+				Summary string `xml:"summary"`
+				Image   struct {
+					HREF string `xml:"href,attr"`
+				} `xml:"image"`
+				MP3 struct {
+					URL string `xml:"url,attr"`
+				} `xml:"enclosure"`
+				Category []string `xml:"category"`
+				Chapters struct {
+					Chapter []struct {
+						Start string `xml:"start,attr"`
+						Title string `xml:"title,attr"`
+					} `xml:"chapter"`
+				} `xml:"chapters"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+
+	if err := xml.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("could not decode feed: %v", err)
+	}
+	if len(data.Channel) == 0 {
+		return nil, fmt.Errorf("feed %s has no channel", s.url)
+	}
+
+	var eps []Episode
+	for _, i := range data.Channel[0].Item {
+		var chapters []Chapter
+		for _, c := range i.Chapters.Chapter {
+			start, err := parseChapterStart(c.Start)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse chapter start %q: %v", c.Start, err)
+			}
+			chapters = append(chapters, Chapter{Start: start, Title: c.Title})
+		}
+		eps = append(eps, Episode{
+			Title:    i.Title,
+			Number:   i.Episode,
+			Link:     i.Link,
+			Desc:     i.Summary,
+			MP3:      i.MP3.URL,
+			Tags:     i.Category,
+			Chapters: chapters,
+		})
+	}
+	return eps, nil
+}
+
+// parseChapterStart parses a Podlove Simple Chapters start attribute, which
+// is either HH:MM:SS.mmm or HH:MM:SS.
+func parseChapterStart(s string) (time.Duration, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("expected HH:MM:SS(.mmm), got %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	sec, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRSSSource(t *testing.T) {
+	srv := serveFile(t, "testdata/feed.rss")
+	defer srv.Close()
+
+	src := &rssSource{url: srv.URL}
+	eps, err := src.FetchEpisodes(context.Background())
+	if err != nil {
+		t.Fatalf("FetchEpisodes: %v", err)
+	}
+
+	want := []Episode{{
+		Title:  "Hello, world",
+		Number: 1,
+		Link:   "https://example.com/episodes/1",
+		Desc:   "The first episode.",
+		MP3:    "https://example.com/episodes/1.mp3",
+		Tags:   []string{"podcast", "example"},
+		Chapters: []Chapter{
+			{Start: 0, Title: "Intro"},
+			{Start: 5*time.Minute + 30*time.Second + 500*time.Millisecond, Title: "Main topic"},
+		},
+	}}
+	if !reflect.DeepEqual(eps, want) {
+		t.Errorf("FetchEpisodes() = %+v, want %+v", eps, want)
+	}
+}
+
+func serveFile(t *testing.T, path string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(httptestFileHandler(t, path))
+}
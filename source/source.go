@@ -0,0 +1,85 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package source provides the episode feeds that podcast-to-youtube can
+// read from: podcast RSS and Atom feeds, JSON Feed, and YouTube channels
+// (for re-publishing episodes that were originally sourced from YouTube).
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Episode is a single item read from a Source, ready to be turned into a
+// YouTube upload.
+type Episode struct {
+	Title    string
+	Number   int
+	Link     string
+	Desc     string
+	MP3      string
+	Tags     []string
+	Chapters []Chapter
+}
+
+// Chapter is a single entry parsed from a feed's Podlove Simple Chapters
+// (<psc:chapters>) element, if it has one.
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+// Source fetches the list of episodes to consider for upload.
+type Source interface {
+	// FetchEpisodes returns every episode currently published by the
+	// source, in no particular order.
+	FetchEpisodes(ctx context.Context) ([]Episode, error)
+}
+
+// Config holds the parameters needed to construct any of the supported
+// sources. Fields that don't apply to the selected backend are ignored.
+type Config struct {
+	// URL is the feed URL for the rss, atom, and jsonfeed backends.
+	URL string
+
+	// ChannelID is the YouTube channel ID for the youtube backend.
+	ChannelID string
+
+	// Client is the HTTP client used for youtube; the rss, atom, and
+	// jsonfeed backends use http.DefaultClient.
+	Client *http.Client
+
+	// YTDLPBin is the yt-dlp binary used by the youtube backend to resolve
+	// each video's direct audio URL. Defaults to "yt-dlp".
+	YTDLPBin string
+}
+
+// New builds the Source named by kind, one of "rss", "atom", "jsonfeed", or
+// "youtube".
+func New(kind string, cfg Config) (Source, error) {
+	switch kind {
+	case "rss", "":
+		return &rssSource{url: cfg.URL}, nil
+	case "atom":
+		return &atomSource{url: cfg.URL}, nil
+	case "jsonfeed":
+		return &jsonFeedSource{url: cfg.URL}, nil
+	case "youtube":
+		return newYouTubeSource(cfg)
+	default:
+		return nil, fmt.Errorf("unknown source %q", kind)
+	}
+}
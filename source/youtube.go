@@ -0,0 +1,129 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// youtubeSource reads the uploads playlist of a YouTube channel, so that
+// episodes originally published as YouTube videos can be regenerated and
+// re-uploaded. It mirrors the playlist-walking approach used by ytsync. Since
+// YouTube doesn't expose a video's audio as a plain URL, each episode's MP3
+// is resolved with yt-dlp, the same way whisper.cpp is shelled out to in the
+// transcript package.
+type youtubeSource struct {
+	channelID string
+	ytDlpBin  string
+	service   *youtube.Service
+}
+
+func newYouTubeSource(cfg Config) (Source, error) {
+	if cfg.ChannelID == "" {
+		return nil, fmt.Errorf("youtube source requires a channel ID")
+	}
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	service, err := youtube.New(client)
+	if err != nil {
+		return nil, fmt.Errorf("could not create YouTube client: %v", err)
+	}
+	ytDlpBin := cfg.YTDLPBin
+	if ytDlpBin == "" {
+		ytDlpBin = "yt-dlp"
+	}
+	return &youtubeSource{channelID: cfg.ChannelID, ytDlpBin: ytDlpBin, service: service}, nil
+}
+
+func (s *youtubeSource) FetchEpisodes(ctx context.Context) ([]Episode, error) {
+	uploads, err := s.uploadsPlaylist()
+	if err != nil {
+		return nil, err
+	}
+
+	var eps []Episode
+	n := 0
+	pageToken := ""
+	for {
+		call := s.service.PlaylistItems.List([]string{"snippet", "contentDetails"}).
+			PlaylistId(uploads).
+			MaxResults(50).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		res, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("could not list playlist items: %v", err)
+		}
+
+		for _, item := range res.Items {
+			n++
+			link := fmt.Sprintf("https://youtu.be/%s", item.ContentDetails.VideoId)
+			mp3, err := s.audioURL(link)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve audio for %s: %v", link, err)
+			}
+			eps = append(eps, Episode{
+				Title:  item.Snippet.Title,
+				Number: n,
+				Link:   link,
+				Desc:   item.Snippet.Description,
+				MP3:    mp3,
+			})
+		}
+
+		pageToken = res.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
+	return eps, nil
+}
+
+// audioURL resolves videoURL to a direct URL for its best-quality audio
+// stream, by asking yt-dlp to print it without downloading anything.
+func (s *youtubeSource) audioURL(videoURL string) (string, error) {
+	cmd := exec.Command(s.ytDlpBin, "-f", "bestaudio", "-g", videoURL)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("yt-dlp failed: %v\n%s", err, out)
+	}
+	url := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if url == "" {
+		return "", fmt.Errorf("yt-dlp returned no audio URL")
+	}
+	return url, nil
+}
+
+// uploadsPlaylist resolves the channel's "uploads" playlist ID, the same
+// one YouTube Studio lists videos under.
+func (s *youtubeSource) uploadsPlaylist() (string, error) {
+	res, err := s.service.Channels.List([]string{"contentDetails"}).Id(s.channelID).Do()
+	if err != nil {
+		return "", fmt.Errorf("could not look up channel %s: %v", s.channelID, err)
+	}
+	if len(res.Items) == 0 {
+		return "", fmt.Errorf("channel %s not found", s.channelID)
+	}
+	return res.Items[0].ContentDetails.RelatedPlaylists.Uploads, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// withFakeYTDlp puts a fake yt-dlp binary that prints url ahead of the real
+// one on PATH, restoring it afterwards.
+func withFakeYTDlp(t *testing.T, url string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake yt-dlp script is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "fake-yt-dlp")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	script := "#!/bin/sh\necho " + url + "\n"
+	path := filepath.Join(dir, "yt-dlp")
+	if err := ioutil.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+	t.Cleanup(func() { os.Setenv("PATH", oldPath) })
+}
+
+func TestYouTubeSource(t *testing.T) {
+	withFakeYTDlp(t, "https://example.com/audio.webm")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		res := &youtube.ChannelListResponse{
+			Items: []*youtube.Channel{{
+				ContentDetails: &youtube.ChannelContentDetails{
+					RelatedPlaylists: &youtube.ChannelContentDetailsRelatedPlaylists{
+						Uploads: "UUuploads",
+					},
+				},
+			}},
+		}
+		writeJSON(t, w, res)
+	})
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		res := &youtube.PlaylistItemListResponse{
+			Items: []*youtube.PlaylistItem{{
+				Snippet: &youtube.PlaylistItemSnippet{
+					Title:       "Episode one",
+					Description: "The first episode.",
+				},
+				ContentDetails: &youtube.PlaylistItemContentDetails{
+					VideoId: "abc123",
+				},
+			}},
+		}
+		writeJSON(t, w, res)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	service, err := youtube.New(http.DefaultClient)
+	if err != nil {
+		t.Fatalf("youtube.New: %v", err)
+	}
+	service.BasePath = srv.URL + "/"
+
+	src := &youtubeSource{channelID: "UCtest", ytDlpBin: "yt-dlp", service: service}
+	eps, err := src.FetchEpisodes(context.Background())
+	if err != nil {
+		t.Fatalf("FetchEpisodes: %v", err)
+	}
+
+	want := []Episode{{
+		Title:  "Episode one",
+		Number: 1,
+		Link:   "https://youtu.be/abc123",
+		Desc:   "The first episode.",
+		MP3:    "https://example.com/audio.webm",
+	}}
+	if !reflect.DeepEqual(eps, want) {
+		t.Errorf("FetchEpisodes() = %+v, want %+v", eps, want)
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Fatalf("encode response: %v", err)
+	}
+}
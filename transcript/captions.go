@@ -0,0 +1,48 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// UploadCaptions uploads the SRT file at srtPath as an English caption
+// track for videoID, using service.
+func UploadCaptions(service *youtube.Service, videoID, srtPath string) error {
+	f, err := os.Open(srtPath)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %v", srtPath, err)
+	}
+	defer f.Close()
+
+	caption := &youtube.Caption{
+		Snippet: &youtube.CaptionSnippet{
+			VideoId:  videoID,
+			Language: "en",
+			Name:     "English",
+			IsDraft:  false,
+		},
+	}
+	_, err = service.Captions.Insert([]string{"snippet"}, caption).
+		Media(f, googleapi.ContentType("application/x-subrip")).
+		Do()
+	if err != nil {
+		return fmt.Errorf("could not upload captions for %s: %v", videoID, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import "strings"
+
+// windowSize is the number of SRT cues grouped into a single sliding window
+// before computing its TF-IDF vector.
+const windowSize = 8
+
+// titleTermCount is the number of top TF-IDF terms used to build a chapter
+// title.
+const titleTermCount = 3
+
+// Segment splits the SRT transcript srt into topical chapters: it groups
+// cues into fixed-size sliding windows, builds a TF-IDF vector for each
+// window, and starts a new chapter wherever the cosine similarity between
+// consecutive windows drops below threshold. Chapter titles are the top
+// TF-IDF terms of the window that starts them. It does not depend on
+// whisper.cpp or any other external process, so it can be unit tested on
+// its own.
+func Segment(srt string, threshold float64) []Chapter {
+	cues, err := parseSRT(srt)
+	if err != nil || len(cues) == 0 {
+		return nil
+	}
+
+	var windows []cue
+	var tokens [][]string
+	for i := 0; i < len(cues); i += windowSize {
+		end := i + windowSize
+		if end > len(cues) {
+			end = len(cues)
+		}
+		var text []string
+		for _, c := range cues[i:end] {
+			text = append(text, c.Text)
+		}
+		windows = append(windows, cue{Start: cues[i].Start, Text: strings.Join(text, " ")})
+		tokens = append(tokens, tokenize(windows[len(windows)-1].Text))
+	}
+
+	tfs := make([]map[string]float64, len(tokens))
+	for i, t := range tokens {
+		tfs[i] = termFreqs(t)
+	}
+	idf := inverseDocFreqs(tfs)
+
+	vecs := make([]map[string]float64, len(tfs))
+	for i, tf := range tfs {
+		vecs[i] = tfidfVector(tf, idf)
+	}
+
+	chapters := []Chapter{{Start: windows[0].Start, Title: chapterTitle(vecs[0])}}
+	for i := 1; i < len(windows); i++ {
+		if cosineSimilarity(vecs[i-1], vecs[i]) < threshold {
+			chapters = append(chapters, Chapter{Start: windows[i].Start, Title: chapterTitle(vecs[i])})
+		}
+	}
+	return chapters
+}
+
+// chapterTitle renders the top TF-IDF terms of a window as a chapter title.
+func chapterTitle(vec map[string]float64) string {
+	terms := topTerms(vec, titleTermCount)
+	if len(terms) == 0 {
+		return "Chapter"
+	}
+	return strings.Title(strings.Join(terms, " "))
+}
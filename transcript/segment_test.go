@@ -0,0 +1,77 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// twoTopicSRT builds an SRT transcript made of two distinct topics, each
+// repeated across enough cues to fill a full sliding window, so Segment
+// has a real similarity drop to detect.
+func twoTopicSRT(topics ...string) string {
+	var b strings.Builder
+	idx, start := 1, 0
+	for _, topic := range topics {
+		for i := 0; i < windowSize; i++ {
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", idx, srtTimestamp(start), srtTimestamp(start+5), topic)
+			idx++
+			start += 5
+		}
+	}
+	return b.String()
+}
+
+func srtTimestamp(sec int) string {
+	return fmt.Sprintf("%02d:%02d:%02d,000", sec/3600, (sec%3600)/60, sec%60)
+}
+
+func TestSegment(t *testing.T) {
+	srt := twoTopicSRT(
+		"Welcome to the show about databases and storage engines.",
+		"Let's switch gears and talk about kubernetes and containers.",
+	)
+
+	chapters := Segment(srt, 0.15)
+	if len(chapters) != 2 {
+		t.Fatalf("Segment returned %d chapters, want 2: %+v", len(chapters), chapters)
+	}
+	if chapters[0].Start != 0 {
+		t.Errorf("chapters[0].Start = %v, want 0", chapters[0].Start)
+	}
+	if chapters[1].Start == 0 {
+		t.Errorf("chapters[1].Start = 0, want the start of the second topic's window")
+	}
+}
+
+func TestSegmentEmpty(t *testing.T) {
+	if got := Segment("", 0.2); got != nil {
+		t.Errorf("Segment(\"\", ...) = %+v, want nil", got)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	a := map[string]float64{"x": 1, "y": 1}
+	b := map[string]float64{"x": 1, "y": 1}
+	if got := cosineSimilarity(a, b); math.Abs(got-1) > 1e-9 {
+		t.Errorf("cosineSimilarity(a, a) = %v, want 1", got)
+	}
+	c := map[string]float64{"z": 1}
+	if got := cosineSimilarity(a, c); got != 0 {
+		t.Errorf("cosineSimilarity(disjoint) = %v, want 0", got)
+	}
+}
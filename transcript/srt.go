@@ -0,0 +1,88 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cue is a single timed line parsed out of an SRT file.
+type cue struct {
+	Start time.Duration
+	Text  string
+}
+
+// parseSRT parses the blocks of an SRT file (index, timestamp range,
+// text, blank line) into cues, ignoring the index numbers.
+func parseSRT(srt string) ([]cue, error) {
+	var cues []cue
+	for _, block := range strings.Split(strings.ReplaceAll(srt, "\r\n", "\n"), "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		// lines[0] is the cue index, lines[1] is the timestamp range.
+		start, _, err := parseSRTTimestamps(lines[1])
+		if err != nil {
+			continue
+		}
+		cues = append(cues, cue{
+			Start: start,
+			Text:  strings.Join(lines[2:], " "),
+		})
+	}
+	return cues, nil
+}
+
+// parseSRTTimestamps parses a "00:00:01,000 --> 00:00:04,000" line.
+func parseSRTTimestamps(line string) (start, end time.Duration, err error) {
+	parts := strings.SplitN(line, "-->", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid SRT timestamp line %q", line)
+	}
+	start, err = parseSRTTimestamp(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err = parseSRTTimestamp(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseSRTTimestamp parses "00:00:01,000" into a time.Duration.
+func parseSRTTimestamp(s string) (time.Duration, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid SRT timestamp %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	sec, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"testing"
+	"time"
+)
+
+const fixtureSRT = `1
+00:00:00,000 --> 00:00:04,500
+Welcome to the show.
+
+2
+00:01:02,250 --> 00:01:05,000
+Today we talk about databases.
+`
+
+func TestParseSRT(t *testing.T) {
+	cues, err := parseSRT(fixtureSRT)
+	if err != nil {
+		t.Fatalf("parseSRT: %v", err)
+	}
+	want := []cue{
+		{Start: 0, Text: "Welcome to the show."},
+		{Start: time.Minute + 2250*time.Millisecond, Text: "Today we talk about databases."},
+	}
+	if len(cues) != len(want) {
+		t.Fatalf("parseSRT returned %d cues, want %d", len(cues), len(want))
+	}
+	for i, c := range cues {
+		if c != want[i] {
+			t.Errorf("cue %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+func TestParseSRTTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"00:00:00,000", 0},
+		{"00:00:01,500", 1500 * time.Millisecond},
+		{"01:02:03,250", time.Hour + 2*time.Minute + 3*time.Second + 250*time.Millisecond},
+	}
+	for _, tt := range tests {
+		got, err := parseSRTTimestamp(tt.in)
+		if err != nil {
+			t.Errorf("parseSRTTimestamp(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSRTTimestamp(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
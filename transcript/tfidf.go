@@ -0,0 +1,129 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var wordRE = regexp.MustCompile(`[a-z0-9']+`)
+
+// stopWords are common English words excluded from TF-IDF vectors so they
+// don't dominate chapter titles or similarity scores.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true, "you": true, "i": true,
+	"we": true, "so": true, "just": true, "like": true, "really": true,
+}
+
+// tokenize lowercases text and splits it into the words used for TF-IDF,
+// dropping stop words.
+func tokenize(text string) []string {
+	var tokens []string
+	for _, w := range wordRE.FindAllString(strings.ToLower(text), -1) {
+		if !stopWords[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// termFreqs returns the term frequency of each token in tokens.
+func termFreqs(tokens []string) map[string]float64 {
+	freqs := map[string]float64{}
+	for _, t := range tokens {
+		freqs[t]++
+	}
+	for t := range freqs {
+		freqs[t] /= float64(len(tokens))
+	}
+	return freqs
+}
+
+// inverseDocFreqs computes the IDF of every term across docs (here, the
+// sliding windows of the transcript).
+func inverseDocFreqs(docs []map[string]float64) map[string]float64 {
+	df := map[string]float64{}
+	for _, doc := range docs {
+		for t := range doc {
+			df[t]++
+		}
+	}
+	idf := map[string]float64{}
+	n := float64(len(docs))
+	for t, count := range df {
+		idf[t] = math.Log(1 + n/count)
+	}
+	return idf
+}
+
+// tfidfVector combines a document's term frequencies with the corpus IDF.
+func tfidfVector(tf, idf map[string]float64) map[string]float64 {
+	vec := make(map[string]float64, len(tf))
+	for t, f := range tf {
+		vec[t] = f * idf[t]
+	}
+	return vec
+}
+
+// cosineSimilarity returns the cosine similarity of two sparse vectors,
+// in [0, 1] for non-negative weights like TF-IDF.
+func cosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for t, va := range a {
+		dot += va * b[t]
+		normA += va * va
+	}
+	for _, vb := range b {
+		normB += vb * vb
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topTerms returns the n highest-weighted terms in vec, in decreasing
+// order of weight.
+func topTerms(vec map[string]float64, n int) []string {
+	type termWeight struct {
+		term   string
+		weight float64
+	}
+	tw := make([]termWeight, 0, len(vec))
+	for t, w := range vec {
+		tw = append(tw, termWeight{t, w})
+	}
+	sort.Slice(tw, func(i, j int) bool {
+		if tw[i].weight != tw[j].weight {
+			return tw[i].weight > tw[j].weight
+		}
+		return tw[i].term < tw[j].term
+	})
+	if len(tw) > n {
+		tw = tw[:n]
+	}
+	terms := make([]string, len(tw))
+	for i, t := range tw {
+		terms[i] = t.term
+	}
+	return terms
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transcript turns a podcast episode's audio into a transcript,
+// and the transcript into chapter markers: a local whisper.cpp binary
+// produces an SRT caption file, which Segment then splits into topical
+// chapters by the similarity of sliding windows of sentences.
+package transcript
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Chapter is a single detected topic change, ready to be embedded in a
+// video description or as chapter metadata.
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+// Transcribe runs the whisper.cpp binary at whisperBin against the audio
+// at mp3Path and returns the path to the SRT file it produces. It
+// requires a whisper.cpp build and model to be available; see
+// https://github.com/ggerganov/whisper.cpp for build instructions.
+func Transcribe(whisperBin, mp3Path, modelPath, outDir string) (string, error) {
+	out := filepath.Join(outDir, "transcript")
+	cmd := exec.Command(whisperBin, "-m", modelPath, "-f", mp3Path, "-osrt", "-of", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("whisper.cpp failed: %v\n%s", err, output)
+	}
+	return out + ".srt", nil
+}
+
+// DescriptionChapters renders chapters as "H:MM:SS Chapter title" lines
+// ("M:SS Chapter title" under an hour), one per line, in the format YouTube
+// auto-detects as video chapters.
+func DescriptionChapters(chapters []Chapter) string {
+	var b strings.Builder
+	for _, c := range chapters {
+		fmt.Fprintf(&b, "%s %s\n", formatTimestamp(c.Start), c.Title)
+	}
+	return b.String()
+}
+
+// formatTimestamp renders d as H:MM:SS (or M:SS under an hour), the
+// format YouTube parses in video descriptions.
+func formatTimestamp(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
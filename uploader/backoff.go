@@ -0,0 +1,76 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uploader
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	maxAttempts = 8
+	baseDelay   = time.Second
+	maxDelay    = 2 * time.Minute
+)
+
+// withBackoff retries do until it succeeds, returns a non-retryable error,
+// or maxAttempts is exhausted, sleeping an exponentially increasing,
+// jittered delay between attempts.
+func withBackoff(ctx context.Context, do func() error) error {
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err = do(); err == nil || !retryable(err) {
+			return err
+		}
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		delay := baseDelay * time.Duration(1<<uint(attempt))
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+		delay += time.Duration(rand.Int63n(int64(delay) / 2))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// retryable reports whether err looks like a transient failure worth
+// retrying: a 5xx response, or a quota/rate-limit error from the YouTube
+// API.
+func retryable(err error) bool {
+	gerr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+	if gerr.Code >= 500 {
+		return true
+	}
+	for _, e := range gerr.Errors {
+		switch e.Reason {
+		case "quotaExceeded", "rateLimitExceeded", "userRateLimitExceeded", "backendError":
+			return true
+		}
+	}
+	return false
+}
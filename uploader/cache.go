@@ -0,0 +1,83 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uploader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// upload records a completed upload, so a re-run of the same episode GUID
+// can skip straight to the result instead of uploading the video again.
+type upload struct {
+	VideoID    string `json:"videoId"`
+	UploadedAt string `json:"uploadedAt"`
+}
+
+// cache is a small on-disk, GUID-keyed record of completed uploads. It is
+// safe for concurrent use by multiple uploads in flight at once.
+type cache struct {
+	path string
+
+	mu   sync.Mutex
+	data map[string]upload
+}
+
+func loadCache(path string) (*cache, error) {
+	c := &cache{path: path, data: map[string]upload{}}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *cache) get(guid string) (upload, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.data[guid]
+	return u, ok
+}
+
+func (c *cache) put(guid string, u upload) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[guid] = u
+	return c.save()
+}
+
+func (c *cache) forget(guid string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, guid)
+	return c.save()
+}
+
+// save writes the cache to disk. The caller must hold c.mu.
+func (c *cache) save() error {
+	b, err := json.MarshalIndent(c.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, b, 0o644)
+}
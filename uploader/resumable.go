@@ -0,0 +1,217 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// uploadChunkSize matches the chunk size the generated client used before,
+// so a single upload still shows smooth progress-bar increments. It's a
+// var, rather than a const, so tests can shrink it to exercise multi-chunk
+// and resumed uploads without needing multi-megabyte fixtures.
+var uploadChunkSize int64 = 8 << 20
+
+// resumableUpload uploads the video at path using YouTube's resumable
+// upload protocol directly, rather than through the generated
+// Videos.Insert call: only the raw protocol hands back the session URI
+// this needs to persist, so a process killed mid-upload can continue from
+// the last acknowledged byte on its next run instead of starting over.
+// guid identifies the episode and is used to look up and store that
+// session URI in u.sessions. onProgress is called with the total number
+// of bytes sent so far, including bytes a previous run already uploaded.
+func (u *Uploader) resumableUpload(ctx context.Context, guid string, data *youtube.Video, path string, onProgress func(sent int64)) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	uri, sent, err := u.resumeSession(ctx, guid, data, size)
+	if err != nil {
+		return "", err
+	}
+	onProgress(sent)
+
+	buf := make([]byte, int(uploadChunkSize))
+	for sent < size {
+		if _, err := f.Seek(sent, io.SeekStart); err != nil {
+			return "", err
+		}
+		n, err := io.ReadFull(f, buf)
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uri, bytes.NewReader(buf[:n]))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", sent, sent+int64(n)-1, size))
+		req.ContentLength = int64(n)
+
+		res, err := u.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		videoID, committed, err := parseUploadResponse(res, size)
+		if err != nil {
+			return "", err
+		}
+		if videoID != "" {
+			u.sessions.delete(guid)
+			return videoID, nil
+		}
+		sent = committed
+		onProgress(sent)
+	}
+	return "", fmt.Errorf("uploaded all %d bytes of %s but YouTube never confirmed completion", size, path)
+}
+
+// resumeSession returns the resumable session URI for guid and how many
+// bytes of it YouTube has already acknowledged, reusing the session
+// persisted in u.sessions if one is still valid and initiating a new one
+// otherwise.
+func (u *Uploader) resumeSession(ctx context.Context, guid string, data *youtube.Video, size int64) (uri string, sent int64, err error) {
+	if cached, ok := u.sessions.get(guid); ok {
+		if committed, ok, err := queryUploadProgress(ctx, u.client, cached, size); err != nil {
+			return "", 0, err
+		} else if ok {
+			return cached, committed, nil
+		}
+		// The cached session expired or was rejected; fall through and
+		// start a fresh one below.
+	}
+
+	uri, err = u.initiateSession(ctx, data, size)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := u.sessions.put(guid, uri); err != nil {
+		return "", 0, err
+	}
+	return uri, 0, nil
+}
+
+// initiateSession asks YouTube for a new resumable upload session for the
+// video described by data and returns the session URI from the response's
+// Location header.
+func (u *Uploader) initiateSession(ctx context.Context, data *youtube.Video, size int64) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	url := googleapi.ResolveRelative(u.service.BasePath, "/upload/youtube/v3/videos") +
+		"?uploadType=resumable&part=snippet,status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", "video/*")
+	req.Header.Set("X-Upload-Content-Length", fmt.Sprintf("%d", size))
+
+	res, err := u.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if err := googleapi.CheckResponse(res); err != nil {
+		// Returned unwrapped, so withBackoff can still see the
+		// *googleapi.Error and retry quota and 5xx failures.
+		return "", err
+	}
+
+	loc := res.Header.Get("Location")
+	if loc == "" {
+		return "", fmt.Errorf("resumable upload response had no Location header")
+	}
+	return loc, nil
+}
+
+// queryUploadProgress asks uri how many bytes of a size-byte upload it has
+// committed so far. ok is false if uri is no longer a valid session, in
+// which case the caller should initiate a new one.
+func queryUploadProgress(ctx context.Context, client *http.Client, uri string, size int64) (committed int64, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uri, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	req.ContentLength = 0
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	_, committed, err = parseUploadResponse(res, size)
+	if err != nil {
+		if gerr, isGoogleErr := err.(*googleapi.Error); isGoogleErr && gerr.Code == http.StatusNotFound {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return committed, true, nil
+}
+
+// parseUploadResponse reads res, the response to an upload chunk (or
+// progress query), and reports either the finished video's ID, or how
+// many bytes of the size-byte upload YouTube has committed so far.
+func parseUploadResponse(res *http.Response, size int64) (videoID string, committed int64, err error) {
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", 0, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var v youtube.Video
+		if err := json.Unmarshal(body, &v); err != nil {
+			return "", 0, fmt.Errorf("could not parse upload response: %v", err)
+		}
+		return v.Id, size, nil
+	case 308: // Resume Incomplete
+		rang := res.Header.Get("Range")
+		if rang == "" {
+			return "", 0, nil
+		}
+		var start, end int64
+		if _, err := fmt.Sscanf(rang, "bytes=%d-%d", &start, &end); err != nil {
+			return "", 0, fmt.Errorf("could not parse Range header %q: %v", rang, err)
+		}
+		return "", end + 1, nil
+	default:
+		return "", 0, googleapi.CheckResponseWithBody(res, body)
+	}
+}
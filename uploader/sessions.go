@@ -0,0 +1,79 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uploader
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// sessionCache is a small on-disk, GUID-keyed record of in-progress
+// resumable upload session URIs, so an upload interrupted partway through
+// resumes from its last acknowledged byte on the next run instead of
+// starting over. It is safe for concurrent use by multiple uploads in
+// flight at once.
+type sessionCache struct {
+	path string
+
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func loadSessionCache(path string) (*sessionCache, error) {
+	c := &sessionCache{path: path, data: map[string]string{}}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *sessionCache) get(guid string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uri, ok := c.data[guid]
+	return uri, ok
+}
+
+func (c *sessionCache) put(guid, uri string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[guid] = uri
+	return c.save()
+}
+
+func (c *sessionCache) delete(guid string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, guid)
+	return c.save()
+}
+
+// save writes the cache to disk. The caller must hold c.mu.
+func (c *sessionCache) save() error {
+	b, err := json.MarshalIndent(c.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, b, 0o644)
+}
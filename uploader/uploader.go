@@ -0,0 +1,143 @@
+// Copyright 2016 Google Inc. All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to writing, software distributed
+// under the License is distributed on a "AS IS" BASIS, WITHOUT WARRANTIES OR
+// CONDITIONS OF ANY KIND, either express or implied.
+//
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package uploader uploads episode videos to YouTube. Unlike a single
+// call.Media(f).Do(), it resumes cleanly after network hiccups, skips
+// episodes a previous run already finished, backs off when YouTube's
+// quota pushes back, and reports progress for multiple concurrent
+// uploads.
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/youtube/v3"
+)
+
+// Uploader uploads videos to YouTube with retries, a persistent cache of
+// completed uploads, a persistent cache of in-progress resumable upload
+// sessions, and a shared quota budget across concurrent uploads.
+type Uploader struct {
+	client   *http.Client
+	service  *youtube.Service
+	cache    *cache
+	sessions *sessionCache
+	limiter  *rate.Limiter
+	bars     *mpb.Progress
+}
+
+// New creates an Uploader. cachePath is where completed uploads are
+// recorded, keyed by episode GUID; it is created if missing, along with a
+// sibling file that tracks in-progress resumable upload sessions. qps
+// bounds how many upload requests per second are issued across all
+// concurrent uploads sharing this Uploader, to stay under YouTube's
+// per-second quota.
+func New(client *http.Client, cachePath string, qps float64) (*Uploader, error) {
+	service, err := youtube.New(client)
+	if err != nil {
+		return nil, fmt.Errorf("could not create YouTube client: %v", err)
+	}
+	c, err := loadCache(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load upload cache %s: %v", cachePath, err)
+	}
+	sessions, err := loadSessionCache(cachePath + ".sessions")
+	if err != nil {
+		return nil, fmt.Errorf("could not load upload session cache %s.sessions: %v", cachePath, err)
+	}
+	return &Uploader{
+		client:   client,
+		service:  service,
+		cache:    c,
+		sessions: sessions,
+		limiter:  rate.NewLimiter(rate.Limit(qps), 1),
+		bars:     mpb.New(mpb.WithOutput(os.Stderr)),
+	}, nil
+}
+
+// Wait blocks until every progress bar registered with this Uploader has
+// finished rendering. Call it once all Upload calls have returned.
+func (u *Uploader) Wait() {
+	u.bars.Wait()
+}
+
+// Service returns the YouTube client backing this Uploader, for callers
+// that need to make further API calls about videos it uploaded, such as
+// inserting captions.
+func (u *Uploader) Service() *youtube.Service {
+	return u.service
+}
+
+// Forget clears guid from both the completed-upload cache and the
+// resumable-session cache, so a later Upload call for the same GUID
+// uploads the video again, in a fresh session, instead of returning the
+// stale result.
+func (u *Uploader) Forget(guid string) error {
+	if err := u.cache.forget(guid); err != nil {
+		return err
+	}
+	return u.sessions.delete(guid)
+}
+
+// Upload uploads the video at path with the given metadata, and returns
+// its YouTube video ID. guid identifies the episode across runs: if guid
+// was already uploaded successfully, Upload returns the cached video ID
+// without touching the network.
+func (u *Uploader) Upload(ctx context.Context, guid string, data *youtube.Video, path string) (string, error) {
+	if cached, ok := u.cache.get(guid); ok {
+		return cached.VideoID, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("could not stat %s: %v", path, err)
+	}
+
+	bar := u.bars.AddBar(info.Size(),
+		mpb.PrependDecorators(decor.Name(data.Snippet.Title, decor.WC{W: 30, C: decor.DidentRight})),
+		mpb.AppendDecorators(decor.Percentage()),
+	)
+	var sent int64
+
+	var videoID string
+	err = withBackoff(ctx, func() error {
+		if err := u.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		id, err := u.resumableUpload(ctx, guid, data, path, func(s int64) {
+			bar.IncrInt64(s - sent)
+			sent = s
+		})
+		if err != nil {
+			return err
+		}
+		videoID = id
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not upload %s: %v", path, err)
+	}
+
+	if err := u.cache.put(guid, upload{VideoID: videoID, UploadedAt: time.Now().UTC().Format(time.RFC3339)}); err != nil {
+		return "", fmt.Errorf("could not record upload in cache: %v", err)
+	}
+	return videoID, nil
+}